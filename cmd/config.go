@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// cfgFile is the optional path passed via --config/-c. When empty, otlpinf
+// runs on flags, environment variables and defaults alone.
+var cfgFile string
+
+// initConfig loads configuration in the precedence order flags > env >
+// file > defaults. Flags are not bound to viper: Run applies the ones the
+// operator actually passed on top of the unmarshalled config, env vars are read
+// under the OTLPINF_ prefix, and the optional --config file is merged in
+// under both. viper only lets an env var override a key that already has
+// a default (or an explicit BindEnv), so every field of config.Config
+// needs one registered here even where the zero value would do.
+func initConfig() {
+	if cfgFile != "" {
+		viper.SetConfigFile(cfgFile)
+		if err := viper.ReadInConfig(); err != nil {
+			cobra.CheckErr(fmt.Errorf("opentelemetry-infinity start up error (config file): %w", err))
+		}
+	}
+
+	viper.SetEnvPrefix("OTLPINF")
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	viper.AutomaticEnv()
+
+	viper.SetDefault("otlp_inf.debug", false)
+	viper.SetDefault("otlp_inf.server_host", "localhost")
+	viper.SetDefault("otlp_inf.server_port", 10222)
+	viper.SetDefault("otlp_inf.shutdown_timeout", "30s")
+	viper.SetDefault("otlp_inf.log_level", "info")
+	viper.SetDefault("otlp_inf.log_format", "json")
+	// bound explicitly (rather than relying on the OTLPINF_OTLP_INF_*
+	// auto-derived name, which no operator would guess) so the env vars
+	// are the short, memorable OTLPINF_DEBUG / OTLPINF_SERVER_HOST /
+	// OTLPINF_SERVER_PORT / OTLPINF_LOG_LEVEL / OTLPINF_LOG_FORMAT.
+	cobra.CheckErr(viper.BindEnv("otlp_inf.debug", "OTLPINF_DEBUG"))
+	cobra.CheckErr(viper.BindEnv("otlp_inf.server_host", "OTLPINF_SERVER_HOST"))
+	cobra.CheckErr(viper.BindEnv("otlp_inf.server_port", "OTLPINF_SERVER_PORT"))
+	cobra.CheckErr(viper.BindEnv("otlp_inf.log_level", "OTLPINF_LOG_LEVEL"))
+	cobra.CheckErr(viper.BindEnv("otlp_inf.log_format", "OTLPINF_LOG_FORMAT"))
+
+	viper.SetDefault("telemetry.enabled", false)
+	viper.SetDefault("telemetry.endpoint", "")
+	viper.SetDefault("telemetry.stdout", false)
+	viper.SetDefault("telemetry.sampling_ratio", 1.0)
+	viper.SetDefault("telemetry.resource_attributes", map[string]string{})
+
+	viper.SetDefault("collectors", map[string]interface{}{})
+}
+
+// newConfigCmd wires up "config print-initial", which dumps the fully
+// resolved configuration (defaults, file and env merged together, before
+// per-run flag overrides) as YAML, mirroring the debugging command users
+// of OpenGFW and the OTel Collector already know.
+func newConfigCmd() *cobra.Command {
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect otlpinf configuration",
+	}
+
+	configCmd.AddCommand(&cobra.Command{
+		Use:   "print-initial",
+		Short: "Print the fully-resolved configuration and exit",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			initConfig()
+			out, err := yaml.Marshal(viper.AllSettings())
+			if err != nil {
+				return err
+			}
+			fmt.Print(string(out))
+			return nil
+		},
+	})
+
+	return configCmd
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// encoderBuilders maps a --log-format value to the zapcore.Encoder it
+// produces. "console" is meant for local development: colorized levels
+// and human-readable timestamps. "json" is the default, matching what
+// most log shippers expect and what otlpinf has always emitted.
+var encoderBuilders = map[string]func(zapcore.EncoderConfig) zapcore.Encoder{
+	"json": func(cfg zapcore.EncoderConfig) zapcore.Encoder {
+		return zapcore.NewJSONEncoder(cfg)
+	},
+	"console": func(cfg zapcore.EncoderConfig) zapcore.Encoder {
+		cfg.EncodeLevel = zapcore.CapitalColorLevelEncoder
+		return zapcore.NewConsoleEncoder(cfg)
+	},
+}
+
+// newEncoder builds the zapcore.Encoder for the given --log-format,
+// falling back to JSON for anything unrecognised so a typo in config
+// never turns into a start up failure.
+func newEncoder(format string) zapcore.Encoder {
+	build, ok := encoderBuilders[format]
+	if !ok {
+		build = encoderBuilders["json"]
+	}
+	cfg := zap.NewProductionEncoderConfig()
+	cfg.EncodeTime = zapcore.RFC3339TimeEncoder
+	return build(cfg)
+}
+
+// parseLevel maps a --log-level value to a zapcore.Level, falling back to
+// info for anything unrecognised.
+func parseLevel(level string) zapcore.Level {
+	var l zapcore.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return zapcore.InfoLevel
+	}
+	return l
+}
@@ -5,13 +5,13 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
-	"strings"
 	"syscall"
 
 	_ "embed"
 
 	"github.com/leoparente/otlpinf/config"
 	"github.com/leoparente/otlpinf/otlpinf"
+	"github.com/leoparente/otlpinf/service/telemetry"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
@@ -25,6 +25,8 @@ var (
 	Debug      bool
 	ServerHost string
 	ServerPort uint64
+	LogLevel   string
+	LogFormat  string
 )
 
 func Run(cmd *cobra.Command, args []string) {
@@ -32,40 +34,80 @@ func Run(cmd *cobra.Command, args []string) {
 	initConfig()
 
 	// configuration
-	var config config.Config
-	config.Version = version
-	config.OtlpInf.Debug = Debug
-	config.OtlpInf.ServerHost = ServerHost
-	config.OtlpInf.ServerPort = ServerPort
+	var cfg config.Config
+	cfg.Version = version
 
-	err := viper.Unmarshal(&config)
+	err := viper.Unmarshal(&cfg)
 	if err != nil {
 		cobra.CheckErr(fmt.Errorf("opentelemetry-infinity start up error (config): %w", err))
 		os.Exit(1)
 	}
 
+	// flags take precedence over env/file/defaults, but only the ones the
+	// operator actually passed - otherwise an unset flag's pflag default
+	// would silently clobber a value that came from the environment or a
+	// config file.
+	if cmd.Flags().Changed("debug") {
+		cfg.OtlpInf.Debug = Debug
+	}
+	if cmd.Flags().Changed("server_host") {
+		cfg.OtlpInf.ServerHost = ServerHost
+	}
+	if cmd.Flags().Changed("server_port") {
+		cfg.OtlpInf.ServerPort = ServerPort
+	}
+	if cmd.Flags().Changed("log-level") {
+		cfg.OtlpInf.LogLevel = LogLevel
+	}
+	if cmd.Flags().Changed("log-format") {
+		cfg.OtlpInf.LogFormat = LogFormat
+	}
+
+	// self-telemetry: traces, metrics and logs describing otlpinf's own
+	// control plane, kept separate from the pipelines it supervises.
+	telemetryCtx, telemetryCancel := context.WithCancel(context.Background())
+	defer telemetryCancel()
+	providers, err := telemetry.New(telemetryCtx, version, &cfg.Telemetry)
+	if err != nil {
+		cobra.CheckErr(fmt.Errorf("opentelemetry-infinity start up error (telemetry): %w", err))
+		os.Exit(1)
+	}
+
 	// logger
-	var logger *zap.Logger
 	atomicLevel := zap.NewAtomicLevel()
-	if Debug {
+	atomicLevel.SetLevel(parseLevel(cfg.OtlpInf.LogLevel))
+	if cfg.OtlpInf.Debug {
 		atomicLevel.SetLevel(zap.DebugLevel)
-	} else {
-		atomicLevel.SetLevel(zap.InfoLevel)
 	}
-	encoderCfg := zap.NewProductionEncoderConfig()
-	encoderCfg.EncodeTime = zapcore.ISO8601TimeEncoder
-	core := zapcore.NewCore(
-		zapcore.NewJSONEncoder(encoderCfg),
-		os.Stdout,
-		atomicLevel,
+	core := zapcore.NewTee(
+		zapcore.NewCore(
+			newEncoder(cfg.OtlpInf.LogFormat),
+			os.Stdout,
+			atomicLevel,
+		),
+		providers.Core,
 	)
-	logger = zap.New(core, zap.AddCaller())
+	logger := zap.New(core, zap.AddCaller())
 	defer func(logger *zap.Logger) {
 		_ = logger.Sync()
 	}(logger)
 
+	// dispatch to the foreground run loop, or the platform service
+	// handler when otlpinf is running under a service manager (e.g. the
+	// Windows SCM).
+	if isInteractive() {
+		runInteractive(&cfg, logger, atomicLevel, providers)
+	} else {
+		runService(&cfg, logger, atomicLevel, providers)
+	}
+}
+
+// runInteractive runs otlpinf in the foreground under a POSIX signal loop.
+// This is today's default behaviour, shared by both non-Windows platforms
+// and Windows hosts launched directly from a console.
+func runInteractive(cfg *config.Config, logger *zap.Logger, atomicLevel zap.AtomicLevel, providers *telemetry.Providers) {
 	// new otlpinf
-	a, err := otlpinf.New(logger, &config)
+	a, err := otlpinf.New(logger, cfg, atomicLevel)
 	if err != nil {
 		logger.Error("otlpinf start up error", zap.Error(err))
 		os.Exit(1)
@@ -78,12 +120,26 @@ func Run(cmd *cobra.Command, args []string) {
 	go func() {
 		sigs := make(chan os.Signal, 1)
 		signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM, syscall.SIGKILL)
+		draining := false
 		for {
 			select {
 			case <-sigs:
-				logger.Warn("stop signal received, stopping otlpinf")
-				a.Stop(rootCtx)
-				cancelFunc()
+				if draining {
+					logger.Warn("second stop signal received, terminating immediately")
+					cancelFunc()
+					continue
+				}
+				draining = true
+				logger.Warn("stop signal received, draining otlpinf", zap.Duration("timeout", cfg.OtlpInf.ShutdownTimeout))
+				go func() {
+					shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), cfg.OtlpInf.ShutdownTimeout)
+					defer shutdownCancel()
+					a.Shutdown(shutdownCtx)
+					if err := providers.Shutdown(shutdownCtx); err != nil {
+						logger.Warn("telemetry shutdown error", zap.Error(err))
+					}
+					cancelFunc()
+				}()
 			case <-rootCtx.Done():
 				logger.Warn("mainRoutine context cancelled")
 				done <- true
@@ -98,27 +154,17 @@ func Run(cmd *cobra.Command, args []string) {
 		logger.Error("otlpinf startup error", zap.Error(err))
 		os.Exit(1)
 	}
+	notifyReady(logger)
 
 	<-done
 }
 
-func initConfig() {
-	v := viper.New()
-	v.AutomaticEnv()
-	replacer := strings.NewReplacer(".", "_")
-	v.SetEnvKeyReplacer(replacer)
-	// note: viper seems to require a default (or a BindEnv) to be overridden by environment variables
-	v.SetDefault("otlp_inf.debug", false)
-	v.SetDefault("otlp_inf.server_host", "localhost")
-	v.SetDefault("otlp_inf.server_port", 10222)
-	cobra.CheckErr(viper.MergeConfigMap(v.AllSettings()))
-}
-
 func main() {
 
 	rootCmd := &cobra.Command{
 		Use: "opentelemetry-infinity",
 	}
+	rootCmd.PersistentFlags().StringVarP(&cfgFile, "config", "c", "", "Path to a YAML/TOML/JSON config file")
 
 	runCmd := &cobra.Command{
 		Use:   "run",
@@ -130,7 +176,11 @@ func main() {
 	runCmd.PersistentFlags().BoolVarP(&Debug, "debug", "d", false, "Enable verbose (debug level) output")
 	runCmd.PersistentFlags().StringVarP(&ServerHost, "server_host", "a", "localhost", "Define REST Host")
 	runCmd.PersistentFlags().Uint64VarP(&ServerPort, "server_port", "p", 10222, "Define REST Port")
+	runCmd.PersistentFlags().StringVar(&LogLevel, "log-level", "info", "Log level (debug|info|warn|error)")
+	runCmd.PersistentFlags().StringVar(&LogFormat, "log-format", "json", "Log encoding (json|console)")
 
 	rootCmd.AddCommand(runCmd)
+	rootCmd.AddCommand(serviceCmds()...)
+	rootCmd.AddCommand(newConfigCmd())
 	rootCmd.Execute()
 }
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// serviceCmds returns the install/uninstall/start/stop subcommands used to
+// manage otlpinf as a platform service. On Windows these talk to the
+// Service Control Manager; on other platforms they return an error
+// explaining that service management isn't supported there (systemd users
+// should manage otlpinf with a regular unit file instead).
+func serviceCmds() []*cobra.Command {
+	return []*cobra.Command{
+		{
+			Use:   "install",
+			Short: "Install otlpinf as a service",
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return installService(args)
+			},
+		},
+		{
+			Use:   "uninstall",
+			Short: "Uninstall the otlpinf service",
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return uninstallService()
+			},
+		},
+		{
+			Use:   "start",
+			Short: "Start the installed otlpinf service",
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return startService()
+			},
+		},
+		{
+			Use:   "stop",
+			Short: "Stop the running otlpinf service",
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return stopService()
+			},
+		},
+	}
+}
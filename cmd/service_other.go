@@ -0,0 +1,64 @@
+//go:build !windows
+
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/leoparente/otlpinf/config"
+	"github.com/leoparente/otlpinf/service/telemetry"
+	"go.uber.org/zap"
+)
+
+// isInteractive is always true outside of Windows: there's no equivalent
+// service-manager dispatch to special-case, foreground execution under a
+// POSIX signal loop is the only mode.
+func isInteractive() bool { return true }
+
+// runService has no service-manager handler outside Windows; here it just
+// runs otlpinf in the foreground, optionally under a systemd unit (see
+// notifyReady).
+func runService(cfg *config.Config, logger *zap.Logger, atomicLevel zap.AtomicLevel, providers *telemetry.Providers) {
+	runInteractive(cfg, logger, atomicLevel, providers)
+}
+
+func installService(args []string) error {
+	return fmt.Errorf("service management is only supported on windows, manage otlpinf with a systemd unit instead")
+}
+
+func uninstallService() error {
+	return fmt.Errorf("service management is only supported on windows, manage otlpinf with a systemd unit instead")
+}
+
+func startService() error {
+	return fmt.Errorf("service management is only supported on windows, manage otlpinf with a systemd unit instead")
+}
+
+func stopService() error {
+	return fmt.Errorf("service management is only supported on windows, manage otlpinf with a systemd unit instead")
+}
+
+// notifyReady tells systemd that otlpinf has finished starting up, so a
+// unit using `Type=notify` only reports active once the REST server and
+// supervised collectors are actually ready. It's a no-op when otlpinf
+// isn't running under systemd (NOTIFY_SOCKET unset) or on platforms
+// without a notify socket.
+func notifyReady(logger *zap.Logger) {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return
+	}
+
+	conn, err := net.Dial("unixgram", socket)
+	if err != nil {
+		logger.Warn("could not reach systemd notify socket", zap.Error(err))
+		return
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte("READY=1\n")); err != nil {
+		logger.Warn("could not notify systemd of readiness", zap.Error(err))
+	}
+}
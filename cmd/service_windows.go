@@ -0,0 +1,227 @@
+//go:build windows
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/leoparente/otlpinf/config"
+	"github.com/leoparente/otlpinf/otlpinf"
+	"github.com/leoparente/otlpinf/service/telemetry"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/eventlog"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+const serviceName = "otlpinf"
+
+// isInteractive reports whether the process was launched from a console
+// (true) or dispatched by the Windows Service Control Manager (false).
+func isInteractive() bool {
+	is, err := svc.IsWindowsService()
+	if err != nil {
+		return true
+	}
+	return !is
+}
+
+// runService adapts otlpinf's lifecycle to the Windows Service Control
+// Manager: it calls OtlpInf.Start when the SCM starts the service, answers
+// Interrogate requests, and turns Stop/Shutdown control requests into an
+// OtlpInf.Shutdown drain. Log output is additionally written to the
+// Windows Event Log since there's no console attached to read stdout from.
+func runService(cfg *config.Config, logger *zap.Logger, atomicLevel zap.AtomicLevel, providers *telemetry.Providers) {
+	elog, err := eventlog.Open(serviceName)
+	if err == nil {
+		defer elog.Close()
+		logger = logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			return zapcore.NewTee(core, &eventLogCore{elog: elog})
+		}))
+	} else {
+		logger.Warn("could not open windows event log, service will log to stdout only", zap.Error(err))
+	}
+
+	if err := svc.Run(serviceName, &windowsService{logger: logger, cfg: cfg, atomicLevel: atomicLevel, providers: providers}); err != nil {
+		logger.Error("windows service failed", zap.Error(err))
+		os.Exit(1)
+	}
+}
+
+type windowsService struct {
+	logger      *zap.Logger
+	cfg         *config.Config
+	atomicLevel zap.AtomicLevel
+	providers   *telemetry.Providers
+}
+
+func (s *windowsService) Execute(args []string, r <-chan svc.ChangeRequest, status chan<- svc.Status) (svcSpecificEC bool, exitCode uint32) {
+	status <- svc.Status{State: svc.StartPending}
+
+	rootCtx, cancelFunc := context.WithCancel(context.Background())
+
+	a, err := otlpinf.New(s.logger, s.cfg, s.atomicLevel)
+	if err != nil {
+		s.logger.Error("otlpinf start up error", zap.Error(err))
+		return false, 1
+	}
+
+	startErrCh := make(chan error, 1)
+	go func() {
+		startErrCh <- a.Start(rootCtx, cancelFunc)
+	}()
+
+	status <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+
+	for {
+		select {
+		case err := <-startErrCh:
+			if err != nil {
+				s.logger.Error("otlpinf startup error", zap.Error(err))
+				return false, 1
+			}
+		case c := <-r:
+			switch c.Cmd {
+			case svc.Interrogate:
+				status <- c.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				status <- svc.Status{State: svc.StopPending}
+				s.logger.Warn("stop signal received, draining otlpinf", zap.Duration("timeout", s.cfg.OtlpInf.ShutdownTimeout))
+				shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), s.cfg.OtlpInf.ShutdownTimeout)
+				a.Shutdown(shutdownCtx)
+				if err := s.providers.Shutdown(shutdownCtx); err != nil {
+					s.logger.Warn("telemetry shutdown error", zap.Error(err))
+				}
+				shutdownCancel()
+				cancelFunc()
+				status <- svc.Status{State: svc.Stopped}
+				return false, 0
+			}
+		case <-rootCtx.Done():
+			status <- svc.Status{State: svc.Stopped}
+			return false, 0
+		}
+	}
+}
+
+// eventLogCore is a minimal zapcore.Core that forwards log entries to the
+// Windows Event Log, letting otlpinf keep its usual stdout encoder (as
+// chosen by --log-format) while a service also gets messages in the Event
+// Viewer.
+type eventLogCore struct {
+	zapcore.LevelEnabler
+	elog *eventlog.Log
+}
+
+func (c *eventLogCore) Enabled(level zapcore.Level) bool { return true }
+func (c *eventLogCore) With([]zapcore.Field) zapcore.Core { return c }
+func (c *eventLogCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return checked.AddCore(entry, c)
+}
+func (c *eventLogCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	switch {
+	case entry.Level >= zapcore.ErrorLevel:
+		return c.elog.Error(1, entry.Message)
+	case entry.Level >= zapcore.WarnLevel:
+		return c.elog.Warning(1, entry.Message)
+	default:
+		return c.elog.Info(1, entry.Message)
+	}
+}
+func (c *eventLogCore) Sync() error { return nil }
+
+func installService(args []string) error {
+	exePath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("could not determine executable path: %w", err)
+	}
+
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(serviceName)
+	if err == nil {
+		s.Close()
+		return fmt.Errorf("service %s already installed", serviceName)
+	}
+
+	s, err = m.CreateService(serviceName, exePath, mgr.Config{
+		DisplayName: "OpenTelemetry Infinity",
+		Description: "Supervises OpenTelemetry collector instances",
+		StartType:   mgr.StartAutomatic,
+	}, "run")
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	if err := eventlog.InstallAsEventCreate(serviceName, eventlog.Error|eventlog.Warning|eventlog.Info); err != nil {
+		s.Delete()
+		return fmt.Errorf("could not register event log source: %w", err)
+	}
+
+	return nil
+}
+
+func uninstallService() error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(serviceName)
+	if err != nil {
+		return fmt.Errorf("service %s is not installed: %w", serviceName, err)
+	}
+	defer s.Close()
+
+	if err := s.Delete(); err != nil {
+		return err
+	}
+
+	return eventlog.Remove(serviceName)
+}
+
+func startService() error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(serviceName)
+	if err != nil {
+		return fmt.Errorf("service %s is not installed: %w", serviceName, err)
+	}
+	defer s.Close()
+
+	return s.Start()
+}
+
+func stopService() error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(serviceName)
+	if err != nil {
+		return fmt.Errorf("service %s is not installed: %w", serviceName, err)
+	}
+	defer s.Close()
+
+	_, err = s.Control(svc.Stop)
+	return err
+}
+
+// notifyReady is a no-op on Windows: service state transitions are
+// reported to the SCM directly via svc.Status in windowsService.Execute.
+func notifyReady(logger *zap.Logger) {}
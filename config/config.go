@@ -0,0 +1,46 @@
+// Package config defines the configuration schema otlpinf loads via
+// viper from flags, environment variables and an optional config file
+// (see cmd.initConfig), and threads through to the control plane and its
+// self-telemetry.
+package config
+
+import "time"
+
+// Config is the root configuration for otlpinf.
+type Config struct {
+	Version    string               `mapstructure:"-"`
+	OtlpInf    OtlpInf              `mapstructure:"otlp_inf"`
+	Telemetry  Telemetry            `mapstructure:"telemetry"`
+	Collectors map[string]Collector `mapstructure:"collectors"`
+}
+
+// Collector describes a single OpenTelemetry Collector process otlpinf
+// should supervise, keyed by name in Config.Collectors.
+type Collector struct {
+	BinaryPath string   `mapstructure:"binary_path"`
+	ConfigPath string   `mapstructure:"config_path"`
+	Args       []string `mapstructure:"args"`
+}
+
+// OtlpInf holds the settings for otlpinf's own control plane: the REST
+// server, how verbosely it logs, and how long it waits for a graceful
+// drain on shutdown.
+type OtlpInf struct {
+	Debug           bool          `mapstructure:"debug"`
+	ServerHost      string        `mapstructure:"server_host"`
+	ServerPort      uint64        `mapstructure:"server_port"`
+	LogLevel        string        `mapstructure:"log_level"`
+	LogFormat       string        `mapstructure:"log_format"`
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+}
+
+// Telemetry configures otlpinf's self-telemetry: the traces, metrics and
+// logs it emits describing its own control plane, kept separate from the
+// OTLP pipelines flowing through the collectors it supervises.
+type Telemetry struct {
+	Enabled            bool              `mapstructure:"enabled"`
+	Endpoint           string            `mapstructure:"endpoint"`
+	Stdout             bool              `mapstructure:"stdout"`
+	SamplingRatio      float64           `mapstructure:"sampling_ratio"`
+	ResourceAttributes map[string]string `mapstructure:"resource_attributes"`
+}
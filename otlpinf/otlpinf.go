@@ -0,0 +1,341 @@
+// Package otlpinf implements the otlpinf control plane: a REST API and a
+// supervisor that keeps a fleet of OpenTelemetry Collector processes
+// running, restarting any that exit unexpectedly.
+package otlpinf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os/exec"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/leoparente/otlpinf/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const instrumentationName = "github.com/leoparente/otlpinf/otlpinf"
+
+// collector is a single supervised OpenTelemetry Collector process.
+// superviseCollector replaces cmd and done across a restart, and does so
+// only while holding OtlpInf.mu; every other goroutine reads them only
+// while holding OtlpInf.mu.
+type collector struct {
+	id   string
+	cmd  *exec.Cmd
+	done chan struct{} // closed by superviseCollector once cmd has exited
+}
+
+// OtlpInf is the otlpinf control plane: it serves the REST API and
+// supervises the collector processes started on its behalf.
+type OtlpInf struct {
+	logger      *zap.Logger
+	cfg         *config.Config
+	atomicLevel zap.AtomicLevel
+
+	httpServer *http.Server
+
+	mu         sync.Mutex
+	collectors map[string]*collector
+
+	tracer          trace.Tracer
+	requestCount    metric.Int64Counter
+	requestLatency  metric.Float64Histogram
+	collectorsGauge metric.Int64ObservableGauge
+	restartCount    metric.Int64Counter
+}
+
+// New builds an OtlpInf ready to be started. atomicLevel is the same
+// level controlling the caller's zap.Logger; New exposes it over the
+// REST API so the log level can be changed at runtime without a
+// restart. Self-telemetry instruments (the request counter/histogram,
+// the running-collectors gauge and the restart counter) are registered
+// against the global MeterProvider installed by telemetry.New, so
+// they're no-ops until self-telemetry is enabled.
+func New(logger *zap.Logger, cfg *config.Config, atomicLevel zap.AtomicLevel) (*OtlpInf, error) {
+	o := &OtlpInf{
+		logger:      logger,
+		cfg:         cfg,
+		atomicLevel: atomicLevel,
+		collectors:  make(map[string]*collector),
+		tracer:      otel.Tracer(instrumentationName),
+	}
+
+	meter := otel.Meter(instrumentationName)
+
+	var err error
+	o.requestCount, err = meter.Int64Counter(
+		"otlpinf.rest.requests",
+		metric.WithDescription("Number of REST API requests served by otlpinf"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	o.requestLatency, err = meter.Float64Histogram(
+		"otlpinf.rest.request.duration",
+		metric.WithDescription("Duration of REST API requests served by otlpinf"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	o.restartCount, err = meter.Int64Counter(
+		"otlpinf.collectors.restarts",
+		metric.WithDescription("Number of times otlpinf has restarted a crashed collector"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	o.collectorsGauge, err = meter.Int64ObservableGauge(
+		"otlpinf.collectors.running",
+		metric.WithDescription("Number of collector processes currently supervised by otlpinf"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := meter.RegisterCallback(func(_ context.Context, obs metric.Observer) error {
+		o.mu.Lock()
+		running := int64(len(o.collectors))
+		o.mu.Unlock()
+		obs.ObserveInt64(o.collectorsGauge, running)
+		return nil
+	}, o.collectorsGauge); err != nil {
+		return nil, err
+	}
+
+	return o, nil
+}
+
+// instrument wraps handler so every REST request increments the request
+// counter and records its latency, tagged with the route and method.
+func (o *OtlpInf) instrument(route string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		handler(w, r)
+		attrs := metric.WithAttributes(
+			attribute.String("route", route),
+			attribute.String("method", r.Method),
+		)
+		o.requestCount.Add(r.Context(), 1, attrs)
+		o.requestLatency.Record(r.Context(), time.Since(start).Seconds(), attrs)
+	}
+}
+
+// Start starts the REST server and reloads the collectors it should be
+// supervising. It returns once the server is listening; the server
+// itself runs in the background until Shutdown is called.
+func (o *OtlpInf) Start(ctx context.Context, cancelFunc context.CancelFunc) error {
+	ctx, span := o.tracer.Start(ctx, "otlpinf.Start")
+	defer span.End()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/health", o.instrument("/api/v1/health", o.health))
+	// zap.AtomicLevel is already an http.Handler (GET returns the current
+	// level, PUT with a {"level":"..."} body changes it), so operators can
+	// turn up verbosity on a running otlpinf without a restart.
+	mux.Handle("/api/v1/log_level", o.atomicLevel)
+
+	o.httpServer = &http.Server{
+		Addr:    net.JoinHostPort(o.cfg.OtlpInf.ServerHost, strconv.FormatUint(o.cfg.OtlpInf.ServerPort, 10)),
+		Handler: mux,
+	}
+
+	ln, err := net.Listen("tcp", o.httpServer.Addr)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		if err := o.httpServer.Serve(ln); err != nil && err != http.ErrServerClosed {
+			o.logger.Error("rest server error", zap.Error(err))
+			cancelFunc()
+		}
+	}()
+
+	return o.reload(ctx)
+}
+
+// reload (re-)reconciles the set of running collectors against
+// cfg.Collectors, starting (and then supervising) any collector that's
+// configured but not yet running. It is the extension point both Start
+// and the (out-of-scope-here) collector-management REST handlers call
+// into; it never stops or restarts a collector that's already running,
+// even if its definition changed.
+func (o *OtlpInf) reload(ctx context.Context) error {
+	ctx, span := o.tracer.Start(ctx, "otlpinf.reload")
+	defer span.End()
+
+	o.mu.Lock()
+	running := make(map[string]struct{}, len(o.collectors))
+	for id := range o.collectors {
+		running[id] = struct{}{}
+	}
+	o.mu.Unlock()
+
+	for id, def := range o.cfg.Collectors {
+		if _, ok := running[id]; ok {
+			continue
+		}
+
+		def := def
+		newCmd := func() (*exec.Cmd, error) {
+			args := append([]string{"--config", def.ConfigPath}, def.Args...)
+			return exec.Command(def.BinaryPath, args...), nil
+		}
+		if err := o.StartCollector(ctx, id, newCmd); err != nil {
+			o.logger.Error("could not start collector", zap.String("collector", id), zap.Error(err))
+		}
+	}
+
+	return nil
+}
+
+// Shutdown drains otlpinf: it stops the REST server with http.Server's
+// own graceful Shutdown (letting in-flight requests finish), then stops
+// every supervised collector, sending each SIGTERM and escalating to
+// SIGKILL for any that haven't exited once ctx is done. Callers
+// typically derive ctx from config.OtlpInf.ShutdownTimeout.
+func (o *OtlpInf) Shutdown(ctx context.Context) error {
+	ctx, span := o.tracer.Start(ctx, "otlpinf.Shutdown")
+	defer span.End()
+
+	var errs []error
+	if o.httpServer != nil {
+		if err := o.httpServer.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("rest server shutdown: %w", err))
+		}
+	}
+
+	o.mu.Lock()
+	collectors := make([]*collector, 0, len(o.collectors))
+	for id, c := range o.collectors {
+		collectors = append(collectors, c)
+		delete(o.collectors, id)
+	}
+	o.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, c := range collectors {
+		wg.Add(1)
+		go func(c *collector) {
+			defer wg.Done()
+			o.stopCollector(ctx, c)
+		}(c)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// stopCollector sends SIGTERM to a supervised collector and escalates to
+// SIGKILL if it hasn't exited by the time ctx is done. It waits on done
+// rather than calling cmd.Wait() itself, since superviseCollector already
+// owns that call for this process.
+func (o *OtlpInf) stopCollector(ctx context.Context, c *collector) {
+	o.mu.Lock()
+	proc := c.cmd.Process
+	done := c.done
+	o.mu.Unlock()
+
+	if err := proc.Signal(syscall.SIGTERM); err != nil {
+		o.logger.Warn("could not send SIGTERM to collector, killing", zap.String("collector", c.id), zap.Error(err))
+		_ = proc.Kill()
+	}
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		o.logger.Warn("collector did not exit before shutdown timeout, killing", zap.String("collector", c.id))
+		_ = proc.Kill()
+		<-done
+	}
+}
+
+// StartCollector launches a supervised collector process, tracking it
+// under id and restarting it via newCmd whenever it exits unexpectedly.
+func (o *OtlpInf) StartCollector(ctx context.Context, id string, newCmd func() (*exec.Cmd, error)) error {
+	cmd, err := newCmd()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	c := &collector{id: id, cmd: cmd, done: make(chan struct{})}
+	o.mu.Lock()
+	o.collectors[id] = c
+	o.mu.Unlock()
+
+	go o.superviseCollector(ctx, c, newCmd)
+	return nil
+}
+
+// superviseCollector is the only goroutine that calls cmd.Wait() for a
+// given collector. It restarts the process via newCmd each time it exits
+// unexpectedly, incrementing the restart counter, until the collector is
+// removed from o.collectors (by Shutdown) - at which point it closes
+// done one last time and returns, letting stopCollector know the process
+// is gone for good. If a restart fails (newCmd or cmd.Start returns an
+// error) it logs the error and returns as well, leaving the collector in
+// o.collectors with no running process.
+func (o *OtlpInf) superviseCollector(ctx context.Context, c *collector, newCmd func() (*exec.Cmd, error)) {
+	for {
+		waitErr := c.cmd.Wait()
+
+		o.mu.Lock()
+		_, stillTracked := o.collectors[c.id]
+		close(c.done)
+		o.mu.Unlock()
+		if !stillTracked {
+			return
+		}
+
+		o.logger.Warn("collector exited, restarting", zap.String("collector", c.id), zap.Error(waitErr))
+		o.restartCount.Add(ctx, 1, metric.WithAttributes(attribute.String("collector", c.id)))
+
+		cmd, err := newCmd()
+		if err != nil {
+			o.logger.Error("could not restart collector", zap.String("collector", c.id), zap.Error(err))
+			return
+		}
+		if err := cmd.Start(); err != nil {
+			o.logger.Error("could not restart collector", zap.String("collector", c.id), zap.Error(err))
+			return
+		}
+
+		// Shutdown may have removed this collector from o.collectors while
+		// the restart above was in flight. Re-check before installing the
+		// new process, or it would be a shutdown-proof orphan that nothing
+		// ever signals or waits on.
+		o.mu.Lock()
+		_, stillTracked = o.collectors[c.id]
+		if stillTracked {
+			c.cmd = cmd
+			c.done = make(chan struct{})
+		}
+		o.mu.Unlock()
+		if !stillTracked {
+			_ = cmd.Process.Kill()
+			_ = cmd.Wait()
+			return
+		}
+	}
+}
+
+func (o *OtlpInf) health(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
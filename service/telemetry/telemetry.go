@@ -0,0 +1,182 @@
+// Package telemetry instruments the otlpinf control plane itself: the
+// REST server, the collector supervisor and the CLI process. It is
+// deliberately separate from the OTel pipelines that the supervised
+// collectors run - otlpinf's own traces, metrics and logs describe how
+// otlpinf is behaving, not the telemetry flowing through the collectors
+// it manages.
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/leoparente/otlpinf/config"
+	"go.opentelemetry.io/contrib/bridges/otelzap"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutlog"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/log/global"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.uber.org/zap/zapcore"
+)
+
+// Providers bundles the SDK providers otlpinf uses to instrument itself,
+// plus a zapcore.Core that bridges control-plane log records into the
+// LoggerProvider so they're exported via OTLP alongside traces and
+// metrics. Callers install Core into their zap.Logger and call Shutdown
+// during graceful termination.
+type Providers struct {
+	TracerProvider *sdktrace.TracerProvider
+	MeterProvider  *metric.MeterProvider
+	LoggerProvider *sdklog.LoggerProvider
+	Core           zapcore.Core
+}
+
+// New builds the control-plane TracerProvider, MeterProvider and
+// LoggerProvider described by cfg and installs them as the global OTel
+// providers, so instrumentation anywhere in otlpinf (otel.Tracer(...),
+// otel.Meter(...)) reports through them. It does nothing beyond building
+// a no-op Core when cfg.Enabled is false.
+func New(ctx context.Context, version string, cfg *config.Telemetry) (*Providers, error) {
+	if !cfg.Enabled {
+		return &Providers{Core: zapcore.NewNopCore()}, nil
+	}
+
+	attrs := []attribute.KeyValue{
+		semconv.ServiceNameKey.String("otlpinf"),
+		semconv.ServiceVersionKey.String(version),
+	}
+	for k, v := range cfg.ResourceAttributes {
+		attrs = append(attrs, attribute.String(k, v))
+	}
+
+	res, err := resource.Merge(
+		resource.Default(),
+		resource.NewSchemaless(attrs...),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("telemetry resource: %w", err)
+	}
+
+	tp, err := newTracerProvider(ctx, cfg, res)
+	if err != nil {
+		return nil, fmt.Errorf("telemetry tracer provider: %w", err)
+	}
+
+	mp, err := newMeterProvider(ctx, cfg, res)
+	if err != nil {
+		return nil, fmt.Errorf("telemetry meter provider: %w", err)
+	}
+
+	lp, err := newLoggerProvider(ctx, cfg, res)
+	if err != nil {
+		return nil, fmt.Errorf("telemetry logger provider: %w", err)
+	}
+
+	otel.SetTracerProvider(tp)
+	otel.SetMeterProvider(mp)
+	global.SetLoggerProvider(lp)
+
+	return &Providers{
+		TracerProvider: tp,
+		MeterProvider:  mp,
+		LoggerProvider: lp,
+		Core:           otelzap.NewCore("github.com/leoparente/otlpinf", otelzap.WithLoggerProvider(lp)),
+	}, nil
+}
+
+func newTracerProvider(ctx context.Context, cfg *config.Telemetry, res *resource.Resource) (*sdktrace.TracerProvider, error) {
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.TraceIDRatioBased(cfg.SamplingRatio)),
+	}
+
+	if cfg.Endpoint != "" {
+		exp, err := otlptracegrpc.New(ctx, otlptracegrpc.WithEndpoint(cfg.Endpoint), otlptracegrpc.WithInsecure())
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, sdktrace.WithBatcher(exp))
+	}
+
+	if cfg.Stdout {
+		exp, err := stdouttrace.New()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, sdktrace.WithBatcher(exp))
+	}
+
+	return sdktrace.NewTracerProvider(opts...), nil
+}
+
+func newMeterProvider(ctx context.Context, cfg *config.Telemetry, res *resource.Resource) (*metric.MeterProvider, error) {
+	opts := []metric.Option{metric.WithResource(res)}
+
+	if cfg.Endpoint != "" {
+		exp, err := otlpmetricgrpc.New(ctx, otlpmetricgrpc.WithEndpoint(cfg.Endpoint), otlpmetricgrpc.WithInsecure())
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, metric.WithReader(metric.NewPeriodicReader(exp)))
+	}
+
+	if cfg.Stdout {
+		exp, err := stdoutmetric.New()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, metric.WithReader(metric.NewPeriodicReader(exp)))
+	}
+
+	return metric.NewMeterProvider(opts...), nil
+}
+
+func newLoggerProvider(ctx context.Context, cfg *config.Telemetry, res *resource.Resource) (*sdklog.LoggerProvider, error) {
+	opts := []sdklog.LoggerProviderOption{sdklog.WithResource(res)}
+
+	if cfg.Endpoint != "" {
+		exp, err := otlploggrpc.New(ctx, otlploggrpc.WithEndpoint(cfg.Endpoint), otlploggrpc.WithInsecure())
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, sdklog.WithProcessor(sdklog.NewBatchProcessor(exp)))
+	}
+
+	if cfg.Stdout {
+		exp, err := stdoutlog.New()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, sdklog.WithProcessor(sdklog.NewBatchProcessor(exp)))
+	}
+
+	return sdklog.NewLoggerProvider(opts...), nil
+}
+
+// Shutdown flushes and closes all three providers, giving each the
+// remaining time in ctx. Errors are joined so a single failing exporter
+// doesn't prevent the others from shutting down.
+func (p *Providers) Shutdown(ctx context.Context) error {
+	var errs []error
+	if p.TracerProvider != nil {
+		errs = append(errs, p.TracerProvider.Shutdown(ctx))
+	}
+	if p.MeterProvider != nil {
+		errs = append(errs, p.MeterProvider.Shutdown(ctx))
+	}
+	if p.LoggerProvider != nil {
+		errs = append(errs, p.LoggerProvider.Shutdown(ctx))
+	}
+	return errors.Join(errs...)
+}